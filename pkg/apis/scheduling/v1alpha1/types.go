@@ -0,0 +1,99 @@
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodGroupPhase is the phase of a PodGroup, following the lifecycle a gang
+// moves through from the point it is first seen by the scheduler to the
+// point every member has finished running.
+type PodGroupPhase string
+
+const (
+	// PodGroupPending means the PodGroup has been submitted but the
+	// scheduler has not yet decided whether MinMember can be satisfied.
+	PodGroupPending PodGroupPhase = "Pending"
+	// PodGroupScheduled means MinMember pods have passed Permit and have
+	// been bound to nodes.
+	PodGroupScheduled PodGroupPhase = "Scheduled"
+	// PodGroupRunning means at least MinMember pods in the group are
+	// running.
+	PodGroupRunning PodGroupPhase = "Running"
+	// PodGroupSucceeded means all pods in the group have completed
+	// successfully.
+	PodGroupSucceeded PodGroupPhase = "Succeeded"
+	// PodGroupFailed means the group failed to reach MinMember before
+	// ScheduleTimeoutSeconds elapsed, or a member failed irrecoverably.
+	PodGroupFailed PodGroupPhase = "Failed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodGroup is a collection of pods being scheduled as a gang: all of them,
+// or at least MinMember of them, must be schedulable before any of them is
+// bound to a node. It follows the coscheduling KEP shape so that the
+// plugins in pkg/plugins can be swapped for the upstream implementation
+// without a migration.
+type PodGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PodGroupSpec   `json:"spec,omitempty"`
+	Status PodGroupStatus `json:"status,omitempty"`
+}
+
+// PodGroupSpec represents the template of a pod group.
+type PodGroupSpec struct {
+	// MinMember defines the minimal number of members/tasks to run the pod
+	// group. Below this number, the scheduler keeps the group's pods
+	// waiting in Permit instead of binding any of them.
+	MinMember int32 `json:"minMember,omitempty"`
+
+	// MinResources defines the minimal resources required to run the pod
+	// group, allowing the scheduler to reject the group early when the
+	// cluster clearly cannot satisfy it. This covers resources requested
+	// by the whole group, not a single pod.
+	// +optional
+	MinResources v1.ResourceList `json:"minResources,omitempty"`
+
+	// ScheduleTimeoutSeconds bounds how long the group's pods may sit in
+	// Permit waiting for MinMember to be reached before they are rejected.
+	// +optional
+	ScheduleTimeoutSeconds *int32 `json:"scheduleTimeoutSeconds,omitempty"`
+}
+
+// PodGroupStatus represents the current state of a pod group.
+type PodGroupStatus struct {
+	// Phase is the current phase of the pod group.
+	Phase PodGroupPhase `json:"phase,omitempty"`
+
+	// OccupiedBy is the UID of the consumer of the pod group, such as a
+	// Job, left empty when the group is unowned.
+	// +optional
+	OccupiedBy string `json:"occupiedBy,omitempty"`
+
+	// Scheduled is the number of pods in the group that have been
+	// permitted and bound to a node.
+	Scheduled int32 `json:"scheduled,omitempty"`
+
+	// Running is the number of pods in the group that are running.
+	Running int32 `json:"running,omitempty"`
+
+	// Succeeded is the number of pods in the group that have completed
+	// successfully.
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// Failed is the number of pods in the group that have failed.
+	Failed int32 `json:"failed,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodGroupList is a collection of pod groups.
+type PodGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PodGroup `json:"items"`
+}
@@ -0,0 +1,87 @@
+// Package metrics registers the Prometheus collectors CustomScheduler
+// reports through, and optionally serves them over HTTP so operators don't
+// have to go spelunking through scheduler logs to see why a gang is stuck.
+package metrics
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const subsystem = "custom_scheduler"
+
+var (
+	// PrefilterRejections counts PreFilter rejections by reason:
+	// "below_min_available" or "lister_error". The "no_group" reason counts
+	// pods that the legacy label path lets through because they carry no
+	// group label.
+	PrefilterRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: subsystem + "_prefilter_rejections_total",
+		Help: "Number of pods rejected in PreFilter, by reason.",
+	}, []string{"reason"})
+
+	// ScoreSeconds observes the duration of each Score call (one per node)
+	// and of each NormalizeScore call, as separate observations.
+	ScoreSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: subsystem + "_score_seconds",
+		Help: "Time spent scoring nodes for a pod.",
+	})
+
+	// PodGroupWaiting reports how many pods of a group are currently parked
+	// in Permit's Wait state.
+	PodGroupWaiting = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: subsystem + "_podgroup_waiting",
+		Help: "Pods of a group currently waiting in Permit.",
+	}, []string{"group"})
+
+	// PodGroupPermitTimeoutTotal counts how many times a gang failed to
+	// reach MinMember before its Permit wait timeout elapsed.
+	PodGroupPermitTimeoutTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: subsystem + "_podgroup_permit_timeout_total",
+		Help: "Number of Permit timeouts while waiting for a gang to fill up.",
+	})
+)
+
+var registerOnce sync.Once
+
+// Register adds every collector in this package to the default Prometheus
+// registry. It is safe to call more than once.
+func Register() {
+	registerOnce.Do(func() {
+		prometheus.MustRegister(
+			PrefilterRejections,
+			ScoreSeconds,
+			PodGroupWaiting,
+			PodGroupPermitTimeoutTotal,
+		)
+	})
+}
+
+// ObserveScoreDuration records the elapsed time since start against
+// ScoreSeconds. Called as `defer metrics.ObserveScoreDuration(time.Now())`
+// so callers don't need to import prometheus just to start a timer.
+func ObserveScoreDuration(start time.Time) {
+	ScoreSeconds.Observe(time.Since(start).Seconds())
+}
+
+// StartServer serves /metrics on bindAddress in a background goroutine. A
+// blank bindAddress is a no-op, leaving metrics registered but unexposed.
+func StartServer(bindAddress string) {
+	if bindAddress == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	go func() {
+		if err := http.ListenAndServe(bindAddress, mux); err != nil {
+			log.Printf("metrics server on %s stopped: %v", bindAddress, err)
+		}
+	}()
+}
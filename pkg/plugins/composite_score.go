@@ -0,0 +1,147 @@
+package plugins
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/twpsfirmament/NTHU-Scheduler-Plugin/pkg/podgroup"
+)
+
+// StrategyName selects one of the composite scoring sub-strategies a
+// CustomScheduler can combine in a single Score call.
+type StrategyName string
+
+const (
+	StrategyLeastAllocated     StrategyName = "LeastAllocated"
+	StrategyMostAllocated      StrategyName = "MostAllocated"
+	StrategyBalancedAllocation StrategyName = "BalancedAllocation"
+	StrategyPodGroupAffinity   StrategyName = "PodGroupAffinity"
+)
+
+// StrategySpec is one entry of CustomSchedulerArgs.Strategies: a named
+// sub-strategy, the resource it looks at (ignored by strategies that don't
+// need one, such as BalancedAllocation and PodGroupAffinity), and the
+// weight it contributes to the composite score.
+type StrategySpec struct {
+	Name     StrategyName    `json:"name"`
+	Weight   int64           `json:"weight"`
+	Resource v1.ResourceName `json:"resource,omitempty"`
+}
+
+// compositeScore runs every configured strategy and returns the
+// weight-normalized sum, each sub-score already clamped to
+// [0, MaxNodeScore] by the strategy itself.
+func (cs *CustomScheduler) compositeScore(pod *v1.Pod, nodeInfo *framework.NodeInfo) int64 {
+	var weightedSum, totalWeight int64
+	for _, st := range cs.strategies {
+		weightedSum += cs.strategyScore(st, pod, nodeInfo) * st.Weight
+		totalWeight += st.Weight
+	}
+	if totalWeight == 0 {
+		return 0
+	}
+	return weightedSum / totalWeight
+}
+
+func (cs *CustomScheduler) strategyScore(st StrategySpec, pod *v1.Pod, nodeInfo *framework.NodeInfo) int64 {
+	switch st.Name {
+	case StrategyLeastAllocated:
+		return leastAllocatedScore(pod, nodeInfo, resourceOrMemory(st.Resource))
+	case StrategyMostAllocated:
+		return mostAllocatedScore(pod, nodeInfo, resourceOrMemory(st.Resource))
+	case StrategyBalancedAllocation:
+		return balancedAllocationScore(pod, nodeInfo)
+	case StrategyPodGroupAffinity:
+		return cs.podGroupAffinityScore(pod, nodeInfo)
+	default:
+		return 0
+	}
+}
+
+func resourceOrMemory(name v1.ResourceName) v1.ResourceName {
+	if name == "" {
+		return v1.ResourceMemory
+	}
+	return name
+}
+
+// utilizationPercent returns how much of the node's allocatable resource
+// would be used, as an integer percentage in [0, 100], once pod is added.
+func utilizationPercent(pod *v1.Pod, nodeInfo *framework.NodeInfo, name v1.ResourceName) int64 {
+	allocatable := nodeAllocatable(nodeInfo, name)
+	if allocatable <= 0 {
+		return 0
+	}
+	used := nodeResourceRequested(nodeInfo, name) + podResourceRequest(pod, name)
+	percent := 100 * used / allocatable
+	if percent > 100 {
+		percent = 100
+	}
+	return percent
+}
+
+// leastAllocatedScore favors the node with the most headroom left.
+func leastAllocatedScore(pod *v1.Pod, nodeInfo *framework.NodeInfo, name v1.ResourceName) int64 {
+	return int64(framework.MaxNodeScore) * (100 - utilizationPercent(pod, nodeInfo, name)) / 100
+}
+
+// mostAllocatedScore favors the node that is already the most utilized,
+// i.e. bin-packing.
+func mostAllocatedScore(pod *v1.Pod, nodeInfo *framework.NodeInfo, name v1.ResourceName) int64 {
+	return int64(framework.MaxNodeScore) * utilizationPercent(pod, nodeInfo, name) / 100
+}
+
+// balancedAllocationScore favors nodes whose CPU and memory utilization are
+// close to each other, following the standard 1-variance(cpuFrac, memFrac)
+// formula so a node isn't left lopsided (e.g. CPU-starved but memory-idle).
+func balancedAllocationScore(pod *v1.Pod, nodeInfo *framework.NodeInfo) int64 {
+	cpuFrac := float64(utilizationPercent(pod, nodeInfo, v1.ResourceCPU)) / 100
+	memFrac := float64(utilizationPercent(pod, nodeInfo, v1.ResourceMemory)) / 100
+
+	mean := (cpuFrac + memFrac) / 2
+	variance := ((cpuFrac-mean)*(cpuFrac-mean) + (memFrac-mean)*(memFrac-mean)) / 2
+	balance := 1 - variance
+	if balance < 0 {
+		balance = 0
+	}
+	return int64(balance * float64(framework.MaxNodeScore))
+}
+
+// podGroupAffinityScore favors nodes that already host pods from the same
+// gang as pod, using the node's own PodInfo list rather than a cluster-wide
+// list, so it stays cheap to evaluate per node.
+func (cs *CustomScheduler) podGroupAffinityScore(pod *v1.Pod, nodeInfo *framework.NodeInfo) int64 {
+	group := cs.podGroupKey(pod)
+	if group == "" {
+		return 0
+	}
+
+	var siblings int64
+	for _, podInfo := range nodeInfo.Pods {
+		if podInfo.Pod.Namespace != pod.Namespace {
+			continue
+		}
+		if cs.podGroupKey(podInfo.Pod) == group {
+			siblings++
+		}
+	}
+
+	score := siblings * 20
+	if score > int64(framework.MaxNodeScore) {
+		score = int64(framework.MaxNodeScore)
+	}
+	return score
+}
+
+// podGroupKey returns an identifier for the gang pod belongs to, whichever
+// of the two gang-scheduling mechanisms it uses, or "" if it belongs to
+// none.
+func (cs *CustomScheduler) podGroupKey(pod *v1.Pod) string {
+	if group, ok := pod.Labels[groupNameLabel]; ok && group != "" {
+		return "label:" + group
+	}
+	if name, ok := pod.Annotations[podgroup.AnnotationKey]; ok && name != "" {
+		return "crd:" + name
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package plugins
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestBalancedAllocationScoreBalanced(t *testing.T) {
+	nodeInfo := &framework.NodeInfo{
+		Requested:   &framework.Resource{MilliCPU: 50, Memory: 50},
+		Allocatable: &framework.Resource{MilliCPU: 100, Memory: 100},
+	}
+	pod := &v1.Pod{}
+
+	got := balancedAllocationScore(pod, nodeInfo)
+	if want := int64(framework.MaxNodeScore); got != want {
+		t.Errorf("equal cpu/mem utilization: got %d, want %d", got, want)
+	}
+}
+
+func TestBalancedAllocationScoreImbalanced(t *testing.T) {
+	nodeInfo := &framework.NodeInfo{
+		Requested:   &framework.Resource{MilliCPU: 100, Memory: 0},
+		Allocatable: &framework.Resource{MilliCPU: 100, Memory: 100},
+	}
+	pod := &v1.Pod{}
+
+	// cpuFrac=1, memFrac=0, mean=0.5, variance=0.25, balance=0.75
+	got := balancedAllocationScore(pod, nodeInfo)
+	want := int64(0.75 * float64(framework.MaxNodeScore))
+	if got != want {
+		t.Errorf("lopsided cpu/mem utilization: got %d, want %d", got, want)
+	}
+}
+
+func TestCompositeScoreWeightedAverage(t *testing.T) {
+	cs := &CustomScheduler{
+		strategies: []StrategySpec{
+			{Name: StrategyLeastAllocated, Weight: 1, Resource: v1.ResourceMemory},
+			{Name: StrategyMostAllocated, Weight: 1, Resource: v1.ResourceMemory},
+		},
+	}
+	nodeInfo := &framework.NodeInfo{
+		Requested:   &framework.Resource{Memory: 50},
+		Allocatable: &framework.Resource{Memory: 100},
+	}
+	pod := &v1.Pod{}
+
+	// LeastAllocated scores 50% utilization as MaxNodeScore/2, MostAllocated
+	// scores it the same from the other direction, so the equal-weight
+	// average is exactly half of MaxNodeScore.
+	got := cs.compositeScore(pod, nodeInfo)
+	want := int64(framework.MaxNodeScore) / 2
+	if got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+}
+
+func TestCompositeScoreNoStrategies(t *testing.T) {
+	cs := &CustomScheduler{}
+	if got := cs.compositeScore(&v1.Pod{}, &framework.NodeInfo{}); got != 0 {
+		t.Errorf("no strategies configured: got %d, want 0", got)
+	}
+}
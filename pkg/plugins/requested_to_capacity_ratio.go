@@ -0,0 +1,153 @@
+package plugins
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// requestedToCapacityRatioMode favors nodes whose utilization best matches a
+// user-supplied shape, instead of the raw Least/Most memory heuristic.
+const requestedToCapacityRatioMode string = "RequestedToCapacityRatio"
+
+// UtilizationShapePoint is one (utilization%, score) anchor of the
+// piecewise-linear function operators use to express a bin-pack or spread
+// preference, following the upstream RequestedToCapacityRatio shape.
+type UtilizationShapePoint struct {
+	Utilization int64 `json:"utilization"`
+	Score       int64 `json:"score"`
+}
+
+// ResourceSpec names a resource considered by RequestedToCapacityRatio and
+// how much it should count towards the final score.
+type ResourceSpec struct {
+	Name   v1.ResourceName `json:"name"`
+	Weight int64           `json:"weight"`
+}
+
+// shapeFunc maps a 0-100 utilization percentage to a 0-10 raw score,
+// interpolating linearly between the two nearest shape points and clamping
+// at the endpoints.
+type shapeFunc func(utilization int64) int64
+
+// buildShapeFunc turns a user-supplied shape into a shapeFunc. The points
+// are sorted by utilization so callers can list them in any order. A nil or
+// empty shape yields a function that always returns 0.
+func buildShapeFunc(points []UtilizationShapePoint) shapeFunc {
+	if len(points) == 0 {
+		return func(int64) int64 { return 0 }
+	}
+
+	sorted := make([]UtilizationShapePoint, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Utilization < sorted[j].Utilization })
+
+	return func(utilization int64) int64 {
+		if utilization <= sorted[0].Utilization {
+			return sorted[0].Score
+		}
+		last := sorted[len(sorted)-1]
+		if utilization >= last.Utilization {
+			return last.Score
+		}
+
+		for i := 1; i < len(sorted); i++ {
+			if utilization > sorted[i].Utilization {
+				continue
+			}
+			lo, hi := sorted[i-1], sorted[i]
+			if hi.Utilization == lo.Utilization {
+				return lo.Score
+			}
+			// linear interpolation between the two bracketing points
+			return lo.Score + (hi.Score-lo.Score)*(utilization-lo.Utilization)/(hi.Utilization-lo.Utilization)
+		}
+		return last.Score
+	}
+}
+
+// requestedToCapacityRatioScore sums the pod's own requests with what the
+// node already has requested, evaluates cs.shape per resource in
+// cs.resources, and returns the weighted average scaled to MaxNodeScore.
+func (cs *CustomScheduler) requestedToCapacityRatioScore(pod *v1.Pod, nodeInfo *framework.NodeInfo) int64 {
+	var weightedScore, totalWeight int64
+
+	for _, res := range cs.resources {
+		podRequested := podResourceRequest(pod, res.Name)
+		used := nodeResourceRequested(nodeInfo, res.Name) + podRequested
+		allocatable := nodeAllocatable(nodeInfo, res.Name)
+		if allocatable <= 0 {
+			continue
+		}
+
+		utilization := 100 * used / allocatable
+		if utilization > 100 {
+			utilization = 100
+		}
+
+		rawScore := cs.shape(utilization)
+		weightedScore += rawScore * res.Weight
+		totalWeight += res.Weight
+	}
+
+	if totalWeight == 0 {
+		return 0
+	}
+
+	// rawScore is on a 0-10 scale by shape convention; scale it up to
+	// MaxNodeScore so it composes with the rest of the scoring pipeline.
+	return weightedScore * int64(framework.MaxNodeScore) / (totalWeight * 10)
+}
+
+// podResourceRequest sums a single resource's requests across all of the
+// pod's containers.
+func podResourceRequest(pod *v1.Pod, name v1.ResourceName) int64 {
+	var total int64
+	for _, container := range pod.Spec.Containers {
+		if quantity, ok := container.Resources.Requests[name]; ok {
+			total += quantityValue(name, quantity)
+		}
+	}
+	return total
+}
+
+// nodeResourceRequested returns what nodeInfo already has requested for the
+// given resource.
+func nodeResourceRequested(nodeInfo *framework.NodeInfo, name v1.ResourceName) int64 {
+	switch name {
+	case v1.ResourceCPU:
+		return nodeInfo.Requested.MilliCPU
+	case v1.ResourceMemory:
+		return nodeInfo.Requested.Memory
+	case v1.ResourceEphemeralStorage:
+		return nodeInfo.Requested.EphemeralStorage
+	default:
+		return nodeInfo.Requested.ScalarResources[name]
+	}
+}
+
+// nodeAllocatable returns the node's allocatable amount of the given
+// resource.
+func nodeAllocatable(nodeInfo *framework.NodeInfo, name v1.ResourceName) int64 {
+	switch name {
+	case v1.ResourceCPU:
+		return nodeInfo.Allocatable.MilliCPU
+	case v1.ResourceMemory:
+		return nodeInfo.Allocatable.Memory
+	case v1.ResourceEphemeralStorage:
+		return nodeInfo.Allocatable.EphemeralStorage
+	default:
+		return nodeInfo.Allocatable.ScalarResources[name]
+	}
+}
+
+// quantityValue converts a resource.Quantity to the same unit nodeInfo uses
+// internally: milli-units for CPU, raw units for everything else.
+func quantityValue(name v1.ResourceName, quantity resource.Quantity) int64 {
+	if name == v1.ResourceCPU {
+		return quantity.MilliValue()
+	}
+	return quantity.Value()
+}
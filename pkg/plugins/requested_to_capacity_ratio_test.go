@@ -0,0 +1,46 @@
+package plugins
+
+import "testing"
+
+func TestBuildShapeFuncEmpty(t *testing.T) {
+	fn := buildShapeFunc(nil)
+	if got := fn(50); got != 0 {
+		t.Errorf("empty shape: want 0, got %d", got)
+	}
+}
+
+func TestBuildShapeFuncInterpolatesAndClamps(t *testing.T) {
+	// spread shape: favor empty nodes
+	fn := buildShapeFunc([]UtilizationShapePoint{{Utilization: 0, Score: 10}, {Utilization: 100, Score: 0}})
+
+	cases := []struct {
+		utilization, want int64
+	}{
+		{0, 10},
+		{100, 0},
+		{50, 5},
+		{-10, 10}, // clamp below the lowest point
+		{150, 0},  // clamp above the highest point
+	}
+	for _, c := range cases {
+		if got := fn(c.utilization); got != c.want {
+			t.Errorf("fn(%d) = %d, want %d", c.utilization, got, c.want)
+		}
+	}
+}
+
+func TestBuildShapeFuncMultiplePointsUnsorted(t *testing.T) {
+	// points given out of order; buildShapeFunc must sort them first
+	fn := buildShapeFunc([]UtilizationShapePoint{
+		{Utilization: 100, Score: 10},
+		{Utilization: 0, Score: 0},
+		{Utilization: 50, Score: 5},
+	})
+
+	if got := fn(25); got != 2 {
+		t.Errorf("fn(25) = %d, want 2", got)
+	}
+	if got := fn(75); got != 7 {
+		t.Errorf("fn(75) = %d, want 7", got)
+	}
+}
@@ -6,32 +6,65 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	pgclientset "github.com/twpsfirmament/NTHU-Scheduler-Plugin/pkg/generated/clientset/versioned"
+	pginformers "github.com/twpsfirmament/NTHU-Scheduler-Plugin/pkg/generated/informers/externalversions"
+	"github.com/twpsfirmament/NTHU-Scheduler-Plugin/pkg/metrics"
+	"github.com/twpsfirmament/NTHU-Scheduler-Plugin/pkg/podgroup"
 )
 
 type CustomSchedulerArgs struct {
-	Mode string `json:"mode"`
+	Mode              string `json:"mode"`
+	PermitWaitSeconds int64  `json:"permitWaitSeconds"`
+	// LegacyLabelMode keeps PreFilter on the old podGroup/minAvailable pod
+	// labels instead of the scheduling.nthu.io PodGroup CRD, for clusters
+	// that have not installed the CRD yet.
+	LegacyLabelMode bool `json:"legacyLabelMode"`
+	// Shape and Resources only apply when Mode is RequestedToCapacityRatio;
+	// see requested_to_capacity_ratio.go.
+	Shape     []UtilizationShapePoint `json:"shape,omitempty"`
+	Resources []ResourceSpec          `json:"resources,omitempty"`
+	// Strategies combines several scoring signals into one Score call; see
+	// composite_score.go. When set, it takes precedence over Mode/Shape.
+	Strategies []StrategySpec `json:"strategies,omitempty"`
+	// MetricsBindAddress, if set, serves Prometheus metrics (see
+	// pkg/metrics) on that address, e.g. ":9090".
+	MetricsBindAddress string `json:"metricsBindAddress,omitempty"`
 }
 
 type CustomScheduler struct {
-	handle    framework.Handle
-	scoreMode string
+	handle            framework.Handle
+	scoreMode         string
+	permitWaitSeconds int64
+	legacyLabelMode   bool
+	pgMgr             *podgroup.PodGroupManager
+	shape             shapeFunc
+	resources         []ResourceSpec
+	strategies        []StrategySpec
 }
 
 var _ framework.PreFilterPlugin = &CustomScheduler{}
+var _ framework.PostFilterPlugin = &CustomScheduler{}
+var _ framework.PermitPlugin = &CustomScheduler{}
+var _ framework.ReservePlugin = &CustomScheduler{}
 var _ framework.ScorePlugin = &CustomScheduler{}
 
 // Name is the name of the plugin used in Registry and configurations.
 const (
-	Name              string = "CustomScheduler"
-	groupNameLabel    string = "podGroup"
-	minAvailableLabel string = "minAvailable"
-	leastMode         string = "Least"
-	mostMode          string = "Most"
+	Name                     string = "CustomScheduler"
+	groupNameLabel           string = "podGroup"
+	minAvailableLabel        string = "minAvailable"
+	leastMode                string = "Least"
+	mostMode                 string = "Most"
+	defaultPermitWaitSeconds int64  = 60
 )
 
 func (cs *CustomScheduler) Name() string {
@@ -42,37 +75,88 @@ func (cs *CustomScheduler) Name() string {
 func New(obj runtime.Object, h framework.Handle) (framework.Plugin, error) {
 	cs := CustomScheduler{}
 	mode := leastMode
+	permitWaitSeconds := defaultPermitWaitSeconds
+	var csArgs CustomSchedulerArgs
 	if obj != nil {
 		args := obj.(*runtime.Unknown)
-		var csArgs CustomSchedulerArgs
 		if err := json.Unmarshal(args.Raw, &csArgs); err != nil {
 			fmt.Printf("Error unmarshal: %v\n", err)
 		}
 		mode = csArgs.Mode
-		if mode != leastMode && mode != mostMode {
+		if mode != leastMode && mode != mostMode && mode != requestedToCapacityRatioMode {
 			return nil, fmt.Errorf("invalid mode, got %s", mode)
 		}
+		if csArgs.PermitWaitSeconds > 0 {
+			permitWaitSeconds = csArgs.PermitWaitSeconds
+		}
 	}
+
 	cs.handle = h
 	cs.scoreMode = mode
+	cs.permitWaitSeconds = permitWaitSeconds
+	cs.legacyLabelMode = csArgs.LegacyLabelMode
+	cs.shape = buildShapeFunc(csArgs.Shape)
+	cs.resources = csArgs.Resources
+
+	switch {
+	case len(csArgs.Strategies) > 0:
+		cs.strategies = csArgs.Strategies
+	case mode == leastMode:
+		// translate the legacy single-signal mode into an equivalent
+		// single-strategy config so Score only has one code path to maintain
+		cs.strategies = []StrategySpec{{Name: StrategyLeastAllocated, Weight: 1, Resource: v1.ResourceMemory}}
+	case mode == mostMode:
+		cs.strategies = []StrategySpec{{Name: StrategyMostAllocated, Weight: 1, Resource: v1.ResourceMemory}}
+	}
+
+	if !cs.legacyLabelMode {
+		pgClient, err := pgclientset.NewForConfig(h.KubeConfig())
+		if err != nil {
+			return nil, fmt.Errorf("creating podgroup client: %w", err)
+		}
+		pgInformerFactory := pginformers.NewSharedInformerFactory(pgClient, 0)
+		pgInformer := pgInformerFactory.Scheduling().V1alpha1().PodGroups()
+		cs.pgMgr = podgroup.NewPodGroupManager(pgClient, pgInformer, h.SharedInformerFactory().Core().V1().Pods().Lister())
+		// The plugin lives for the scheduler process's lifetime, so the
+		// informer never needs to be stopped independently of it.
+		pgInformerFactory.Start(wait.NeverStop)
+	}
+
+	metrics.Register()
+	metrics.StartServer(csArgs.MetricsBindAddress)
+
 	log.Printf("Custom scheduler runs with the mode: %s.", mode)
 
 	return &cs, nil
 }
 
-// filter the pod if the pod in group is less than minAvailable
+// PreFilter rejects the pod if its gang does not have enough members yet.
+// When the PodGroup CRD controller is wired in (the default), the check is
+// delegated to cs.pgMgr, which also enforces MinResources. legacyLabelMode
+// falls back to the original podGroup/minAvailable pod labels. Rejections
+// are counted in metrics.PrefilterRejections and, for under-quorum gangs,
+// surfaced on the pod as a PodGroupNotReady event.
 func (cs *CustomScheduler) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
 	log.Printf("Pod %s is in Prefilter phase.", pod.Name)
 	newStatus := framework.NewStatus(framework.Success, "")
 
-	// TODO
-	// 1. extract the label of the pod
-	// 2. retrieve the pod with the same group label
-	// 3. justify if the pod can be scheduled
+	if !cs.legacyLabelMode {
+		nodeInfos, err := cs.handle.SnapshotSharedLister().NodeInfos().List()
+		if err != nil {
+			metrics.PrefilterRejections.WithLabelValues("lister_error").Inc()
+			return nil, framework.NewStatus(framework.Error, err.Error())
+		}
+		status := cs.pgMgr.PreFilter(ctx, pod, nodeInfos)
+		if !status.IsSuccess() {
+			cs.recordPreFilterRejection(pod, status)
+		}
+		return nil, status
+	}
 
 	label, exists := pod.ObjectMeta.Labels[groupNameLabel]
 	minAvailable := pod.ObjectMeta.Labels[minAvailableLabel]
 	if !exists {
+		metrics.PrefilterRejections.WithLabelValues("no_group").Inc()
 		return nil, framework.NewStatus(framework.Success, "no group label")
 	}
 
@@ -80,11 +164,15 @@ func (cs *CustomScheduler) PreFilter(ctx context.Context, state *framework.Cycle
 
 	pods, err := cs.handle.SharedInformerFactory().Core().V1().Pods().Lister().Pods(pod.Namespace).List(selector)
 	if err != nil {
+		metrics.PrefilterRejections.WithLabelValues("lister_error").Inc()
 		return nil, framework.NewStatus(framework.Error, err.Error())
 	}
 
 	minAvailableInt, _ := strconv.Atoi(minAvailable)
 	if len(pods) < minAvailableInt {
+		metrics.PrefilterRejections.WithLabelValues("below_min_available").Inc()
+		cs.handle.EventRecorder().Eventf(pod, nil, v1.EventTypeWarning, "PodGroupNotReady", "PreFilter",
+			"group %s has %d pod(s), want %d", label, len(pods), minAvailableInt)
 		return nil, framework.NewStatus(framework.Unschedulable, "not enough pods in the group")
 	}
 
@@ -92,35 +180,165 @@ func (cs *CustomScheduler) PreFilter(ctx context.Context, state *framework.Cycle
 	return nil, newStatus
 }
 
+// recordPreFilterRejection labels and counts a PodGroupManager rejection,
+// and emits a PodGroupNotReady event for the under-quorum case so users can
+// `kubectl describe pod` instead of reading scheduler logs.
+func (cs *CustomScheduler) recordPreFilterRejection(pod *v1.Pod, status *framework.Status) {
+	reason := "below_min_available"
+	if status.Code() == framework.Error {
+		reason = "lister_error"
+	}
+	metrics.PrefilterRejections.WithLabelValues(reason).Inc()
+
+	if status.Code() == framework.Unschedulable {
+		cs.handle.EventRecorder().Eventf(pod, nil, v1.EventTypeWarning, "PodGroupNotReady", "PreFilter", "%s", status.Message())
+	}
+}
+
 // PreFilterExtensions returns a PreFilterExtensions interface if the plugin implements one.
 func (cs *CustomScheduler) PreFilterExtensions() framework.PreFilterExtensions {
 	return nil
 }
 
+// gangInfo resolves the gang a pod belongs to and the member count it
+// needs before Permit lets it through, agreeing with whichever gang
+// mechanism PreFilter used: the podGroup/minAvailable labels in
+// legacyLabelMode, or the PodGroup CRD via cs.pgMgr otherwise. ok is false
+// if the pod belongs to no gang.
+func (cs *CustomScheduler) gangInfo(pod *v1.Pod) (group string, minMember int32, ok bool) {
+	if cs.legacyLabelMode {
+		label, exists := pod.ObjectMeta.Labels[groupNameLabel]
+		if !exists {
+			return "", 0, false
+		}
+		minAvailableInt, _ := strconv.Atoi(pod.ObjectMeta.Labels[minAvailableLabel])
+		return label, int32(minAvailableInt), true
+	}
+
+	pgName, pg := cs.pgMgr.GetPodGroup(pod)
+	if pgName == "" || pg == nil {
+		return "", 0, false
+	}
+	return pgName, pg.Spec.MinMember, true
+}
+
+// inGang reports whether p belongs to the named gang, using the same
+// mechanism gangInfo resolved it from.
+func (cs *CustomScheduler) inGang(p *v1.Pod, group string) bool {
+	if cs.legacyLabelMode {
+		return p.ObjectMeta.Labels[groupNameLabel] == group
+	}
+	return p.Annotations[podgroup.AnnotationKey] == group
+}
+
+// PostFilter rejects the remaining waiting members of a gang once one of them
+// turns out to be unschedulable, so the group fails fast instead of sitting in
+// Permit's Wait state until its wait timeout elapses.
+func (cs *CustomScheduler) PostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	log.Printf("Pod %s is in PostFilter phase.", pod.Name)
+
+	group, _, ok := cs.gangInfo(pod)
+	if !ok {
+		return nil, framework.NewStatus(framework.Unschedulable, "no group")
+	}
+
+	cs.handle.IterateOverWaitingPods(func(wp framework.WaitingPod) {
+		if wp.GetPod().Namespace == pod.Namespace && cs.inGang(wp.GetPod(), group) {
+			wp.Reject(Name, fmt.Sprintf("gang member %s is unschedulable", pod.Name))
+		}
+	})
+
+	return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("gang %s failed: member %s is unschedulable", group, pod.Name))
+}
+
+// Permit holds a pod until enough siblings from the same gang have also
+// reached the permit phase, implementing true gang scheduling instead of the
+// best-effort check PreFilter does against a possibly-stale informer cache.
+func (cs *CustomScheduler) Permit(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (*framework.Status, time.Duration) {
+	log.Printf("Pod %s is in Permit phase.", pod.Name)
+
+	group, minMember, ok := cs.gangInfo(pod)
+	if !ok || minMember <= 0 {
+		return framework.NewStatus(framework.Success, ""), 0
+	}
+
+	// this pod has reached Permit too, so it counts towards the group
+	reached := int32(1)
+	cs.handle.IterateOverWaitingPods(func(wp framework.WaitingPod) {
+		if wp.GetPod().Namespace == pod.Namespace && cs.inGang(wp.GetPod(), group) {
+			reached++
+		}
+	})
+
+	if reached < minMember {
+		metrics.PodGroupWaiting.WithLabelValues(group).Set(float64(reached))
+		waitDuration := time.Duration(cs.permitWaitSeconds) * time.Second
+		if !cs.legacyLabelMode {
+			if _, pg := cs.pgMgr.GetPodGroup(pod); pg != nil && pg.Spec.ScheduleTimeoutSeconds != nil && *pg.Spec.ScheduleTimeoutSeconds > 0 {
+				waitDuration = time.Duration(*pg.Spec.ScheduleTimeoutSeconds) * time.Second
+			}
+		}
+		return framework.NewStatus(framework.Wait, "waiting for more pods in the group"), waitDuration
+	}
+
+	cs.handle.IterateOverWaitingPods(func(wp framework.WaitingPod) {
+		if wp.GetPod().Namespace == pod.Namespace && cs.inGang(wp.GetPod(), group) {
+			wp.Allow(Name)
+		}
+	})
+	metrics.PodGroupWaiting.WithLabelValues(group).Set(0)
+
+	return framework.NewStatus(framework.Success, ""), 0
+}
+
+// Reserve is a no-op; CustomScheduler only needs the Unreserve half of the
+// ReservePlugin interface.
+func (cs *CustomScheduler) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	return framework.NewStatus(framework.Success, "")
+}
+
+// Unreserve fires for any pod that fails after Permit, including a Permit
+// timeout, which is the only hook the framework offers for that event - it
+// does not call back into the plugin that returned Wait.
+func (cs *CustomScheduler) Unreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
+	if group, _, ok := cs.gangInfo(pod); ok {
+		metrics.PodGroupPermitTimeoutTotal.Inc()
+		metrics.PodGroupWaiting.DeleteLabelValues(group)
+	}
+}
+
 // Score invoked at the score extension point.
 func (cs *CustomScheduler) Score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
 	log.Printf("Pod %s is in Score phase. Calculate the score of Node %s.", pod.Name, nodeName)
+	defer metrics.ObserveScoreDuration(time.Now())
 
-	// TODO
 	// 1. retrieve the node allocatable memory
 	nodeInfo, err := cs.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
 	if err != nil {
 		return 0, framework.NewStatus(framework.Error, err.Error())
 	}
-	nodeMemory := nodeInfo.Node().Status.Allocatable.Memory().Value()
+
 	// 2. return the score based on the scheduler mode
-	if cs.scoreMode == leastMode {
-		return -nodeMemory, nil
-	} else if cs.scoreMode == mostMode {
-		return nodeMemory, nil
+	if len(cs.strategies) > 0 {
+		return cs.compositeScore(pod, nodeInfo), nil
+	}
+	if cs.scoreMode == requestedToCapacityRatioMode {
+		return cs.requestedToCapacityRatioScore(pod, nodeInfo), nil
 	}
 	return 0, nil
 }
 
 // ensure the scores are within the valid range
 func (cs *CustomScheduler) NormalizeScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList) *framework.Status {
-	// TODO
-	// find the range of the current score and map to the valid range
+	defer metrics.ObserveScoreDuration(time.Now())
+
+	// Strategies and RequestedToCapacityRatio already return absolute scores
+	// in [0, MaxNodeScore]; min-max rescaling them would stretch the
+	// utilization curve's intended magnitudes to span the whole range,
+	// distorting composition with other score plugins by weight.
+	if len(cs.strategies) > 0 || cs.scoreMode == requestedToCapacityRatioMode {
+		return framework.NewStatus(framework.Success, "")
+	}
 
 	if len(scores) == 0 {
 		return framework.NewStatus(framework.Success, "No scores to normalize")
@@ -158,7 +376,6 @@ func (cs *CustomScheduler) NormalizeScore(ctx context.Context, state *framework.
 	}
 
 	return framework.NewStatus(framework.Success, "")
-	return nil
 }
 
 // ScoreExtensions of the Score plugin.
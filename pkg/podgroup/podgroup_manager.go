@@ -0,0 +1,151 @@
+// Package podgroup wires the scheduling.nthu.io/v1alpha1 PodGroup CRD into
+// the scheduler, replacing the label-based gang check that used to live
+// directly in pkg/plugins.
+package podgroup
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	schedulingv1alpha1 "github.com/twpsfirmament/NTHU-Scheduler-Plugin/pkg/apis/scheduling/v1alpha1"
+	versioned "github.com/twpsfirmament/NTHU-Scheduler-Plugin/pkg/generated/clientset/versioned"
+	pginformers "github.com/twpsfirmament/NTHU-Scheduler-Plugin/pkg/generated/informers/externalversions/scheduling/v1alpha1"
+	pglisters "github.com/twpsfirmament/NTHU-Scheduler-Plugin/pkg/generated/listers/scheduling/v1alpha1"
+)
+
+// PodGroupLabel is the label pods carry for backward compatibility with the
+// old gang-scheduling implementation; see legacyLabelMode in pkg/plugins.
+const PodGroupLabel = "podGroup"
+
+// AnnotationKey is the annotation a pod sets on itself to reference the
+// PodGroup it belongs to.
+const AnnotationKey = "scheduling.nthu.io/pod-group"
+
+// PodGroupManager resolves a pod's PodGroup and decides whether the group
+// has enough members, and enough room on the cluster, to be worth
+// admitting into the scheduling cycle.
+type PodGroupManager struct {
+	pgClient  versioned.Interface
+	pgLister  pglisters.PodGroupLister
+	podLister corelisters.PodLister
+}
+
+// NewPodGroupManager returns a PodGroupManager backed by the given
+// clientset, informer and the scheduler's own pod lister.
+func NewPodGroupManager(pgClient versioned.Interface, pgInformer pginformers.PodGroupInformer, podLister corelisters.PodLister) *PodGroupManager {
+	return &PodGroupManager{
+		pgClient:  pgClient,
+		pgLister:  pgInformer.Lister(),
+		podLister: podLister,
+	}
+}
+
+// GetPodGroup returns the name of the PodGroup referenced by the pod's
+// AnnotationKey annotation, and the PodGroup object itself if it could be
+// found in the lister. A pod with no annotation returns ("", nil).
+func (mgr *PodGroupManager) GetPodGroup(pod *v1.Pod) (string, *schedulingv1alpha1.PodGroup) {
+	pgName, ok := pod.Annotations[AnnotationKey]
+	if !ok || len(pgName) == 0 {
+		return "", nil
+	}
+
+	pg, err := mgr.pgLister.PodGroups(pod.Namespace).Get(pgName)
+	if err != nil {
+		return pgName, nil
+	}
+	return pgName, pg
+}
+
+// PreFilter rejects the pod when its PodGroup does not yet have MinMember
+// pods present, or when MinResources is larger than what the cluster can
+// ever allocate. A pod with no PodGroup annotation always passes.
+func (mgr *PodGroupManager) PreFilter(ctx context.Context, pod *v1.Pod, nodeInfos []*framework.NodeInfo) *framework.Status {
+	pgName, pg := mgr.GetPodGroup(pod)
+	if pgName == "" {
+		return framework.NewStatus(framework.Success, "")
+	}
+	if pg == nil {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("pod group %s not found", pgName))
+	}
+
+	pods, err := mgr.podLister.Pods(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		return framework.NewStatus(framework.Error, err.Error())
+	}
+
+	var member int32
+	for _, p := range pods {
+		if p.Annotations[AnnotationKey] == pgName {
+			member++
+		}
+	}
+	if member < pg.Spec.MinMember {
+		// Unschedulable, not UnschedulableAndUnresolvable: the gang is still
+		// assembling and should be requeued as siblings get created.
+		return framework.NewStatus(framework.Unschedulable,
+			fmt.Sprintf("pod group %s has %d member(s), want %d", pgName, member, pg.Spec.MinMember))
+	}
+
+	if len(pg.Spec.MinResources) > 0 {
+		allocatable := sumAllocatable(nodeInfos)
+		if exceeds, resourceName := exceedsAllocatable(pg.Spec.MinResources, allocatable); exceeds {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable,
+				fmt.Sprintf("pod group %s requires more %s than the cluster can ever allocate", pgName, resourceName))
+		}
+	}
+
+	return framework.NewStatus(framework.Success, "")
+}
+
+// sumAllocatable adds up the allocatable resources of every node snapshot
+// known to the scheduler.
+func sumAllocatable(nodeInfos []*framework.NodeInfo) *framework.Resource {
+	total := &framework.Resource{}
+	for _, nodeInfo := range nodeInfos {
+		if nodeInfo == nil || nodeInfo.Allocatable == nil {
+			continue
+		}
+		total.MilliCPU += nodeInfo.Allocatable.MilliCPU
+		total.Memory += nodeInfo.Allocatable.Memory
+		total.EphemeralStorage += nodeInfo.Allocatable.EphemeralStorage
+		for name, quantity := range nodeInfo.Allocatable.ScalarResources {
+			if total.ScalarResources == nil {
+				total.ScalarResources = map[v1.ResourceName]int64{}
+			}
+			total.ScalarResources[name] += quantity
+		}
+	}
+	return total
+}
+
+// exceedsAllocatable reports whether any entry in minResources is larger
+// than the cluster's total allocatable for that resource, along with the
+// name of the first resource that fails.
+func exceedsAllocatable(minResources v1.ResourceList, allocatable *framework.Resource) (bool, v1.ResourceName) {
+	for name, want := range minResources {
+		switch name {
+		case v1.ResourceCPU:
+			if want.MilliValue() > allocatable.MilliCPU {
+				return true, name
+			}
+		case v1.ResourceMemory:
+			if want.Value() > allocatable.Memory {
+				return true, name
+			}
+		case v1.ResourceEphemeralStorage:
+			if want.Value() > allocatable.EphemeralStorage {
+				return true, name
+			}
+		default:
+			if want.Value() > allocatable.ScalarResources[name] {
+				return true, name
+			}
+		}
+	}
+	return false, ""
+}
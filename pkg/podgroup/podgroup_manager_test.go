@@ -0,0 +1,125 @@
+package podgroup
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	schedulingv1alpha1 "github.com/twpsfirmament/NTHU-Scheduler-Plugin/pkg/apis/scheduling/v1alpha1"
+	pglisters "github.com/twpsfirmament/NTHU-Scheduler-Plugin/pkg/generated/listers/scheduling/v1alpha1"
+)
+
+func testPod(name, group string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: map[string]string{AnnotationKey: group},
+		},
+	}
+}
+
+func newTestManager(t *testing.T, pg *schedulingv1alpha1.PodGroup, pods []*v1.Pod) *PodGroupManager {
+	t.Helper()
+
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, pod := range pods {
+		if err := podIndexer.Add(pod); err != nil {
+			t.Fatalf("adding pod to indexer: %v", err)
+		}
+	}
+
+	pgIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if pg != nil {
+		if err := pgIndexer.Add(pg); err != nil {
+			t.Fatalf("adding pod group to indexer: %v", err)
+		}
+	}
+
+	return &PodGroupManager{
+		podLister: corelisters.NewPodLister(podIndexer),
+		pgLister:  pglisters.NewPodGroupLister(pgIndexer),
+	}
+}
+
+func TestPreFilterNoAnnotation(t *testing.T) {
+	mgr := newTestManager(t, nil, nil)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default"}}
+
+	status := mgr.PreFilter(context.Background(), pod, nil)
+	if !status.IsSuccess() {
+		t.Fatalf("want success for a pod with no PodGroup annotation, got %v: %s", status.Code(), status.Message())
+	}
+}
+
+func TestPreFilterBelowMinMember(t *testing.T) {
+	pg := &schedulingv1alpha1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "g1", Namespace: "default"},
+		Spec:       schedulingv1alpha1.PodGroupSpec{MinMember: 3},
+	}
+	pod := testPod("p1", "g1")
+	mgr := newTestManager(t, pg, []*v1.Pod{pod})
+
+	status := mgr.PreFilter(context.Background(), pod, nil)
+	if status.Code() != framework.Unschedulable {
+		t.Fatalf("want Unschedulable (so the gang gets requeued as it fills up), got %v: %s", status.Code(), status.Message())
+	}
+}
+
+func TestPreFilterMinMemberSatisfied(t *testing.T) {
+	pg := &schedulingv1alpha1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "g1", Namespace: "default"},
+		Spec:       schedulingv1alpha1.PodGroupSpec{MinMember: 2},
+	}
+	pods := []*v1.Pod{testPod("p1", "g1"), testPod("p2", "g1")}
+	mgr := newTestManager(t, pg, pods)
+
+	status := mgr.PreFilter(context.Background(), pods[0], nil)
+	if !status.IsSuccess() {
+		t.Fatalf("want success once MinMember is reached, got %v: %s", status.Code(), status.Message())
+	}
+}
+
+func TestPreFilterMinResourcesExceedsAllocatable(t *testing.T) {
+	pg := &schedulingv1alpha1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "g1", Namespace: "default"},
+		Spec: schedulingv1alpha1.PodGroupSpec{
+			MinMember:    1,
+			MinResources: v1.ResourceList{v1.ResourceMemory: resource.MustParse("100Gi")},
+		},
+	}
+	pod := testPod("p1", "g1")
+	mgr := newTestManager(t, pg, []*v1.Pod{pod})
+
+	nodeInfo := &framework.NodeInfo{Allocatable: &framework.Resource{Memory: 10 * 1024 * 1024 * 1024}}
+
+	status := mgr.PreFilter(context.Background(), pod, []*framework.NodeInfo{nodeInfo})
+	if status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Fatalf("want UnschedulableAndUnresolvable when the cluster can never fit MinResources, got %v: %s", status.Code(), status.Message())
+	}
+}
+
+func TestPreFilterMinResourcesWithinAllocatable(t *testing.T) {
+	pg := &schedulingv1alpha1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "g1", Namespace: "default"},
+		Spec: schedulingv1alpha1.PodGroupSpec{
+			MinMember:    1,
+			MinResources: v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+		},
+	}
+	pod := testPod("p1", "g1")
+	mgr := newTestManager(t, pg, []*v1.Pod{pod})
+
+	nodeInfo := &framework.NodeInfo{Allocatable: &framework.Resource{Memory: 10 * 1024 * 1024 * 1024}}
+
+	status := mgr.PreFilter(context.Background(), pod, []*framework.NodeInfo{nodeInfo})
+	if !status.IsSuccess() {
+		t.Fatalf("want success when the cluster can fit MinResources, got %v: %s", status.Code(), status.Message())
+	}
+}